@@ -0,0 +1,93 @@
+// Copyright 2015 Alex Browne and Soroush Pour.
+// Allrights reserved. Use of this source code is
+// governed by the MIT license, which can be found
+// in the LICENSE file.
+
+package router
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Context is passed in to every Handler. It holds any information that
+// might be useful to the Handler, such as the parameters parsed from the
+// matched url.
+type Context struct {
+	// Path is the url path that triggered the current route, not
+	// including any query string.
+	Path string
+	// Pattern is the registered route pattern that matched Path (e.g.
+	// "/users/{id}"). It lets a handler that is shared between multiple
+	// routes know which one fired, which is useful for things like
+	// analytics in an SPA with many routes.
+	Pattern string
+	// Params is a map of parameter names to values parsed from Path
+	// according to Pattern. For example, if Pattern is "/users/{id}" and
+	// Path is "/users/24", Params will be {"id": "24"}.
+	Params map[string]string
+	// Query holds any query string parameters (e.g. "?x=1") found after
+	// the "?" in the url that triggered the current route.
+	Query url.Values
+}
+
+// newContext creates and returns a new Context for the given path, the
+// pattern that matched it, the params parsed from it, and the query string
+// parsed from the url.
+func newContext(path string, pattern string, params map[string]string, query url.Values) *Context {
+	return &Context{
+		Path:    path,
+		Pattern: pattern,
+		Params:  params,
+		Query:   query,
+	}
+}
+
+// ParamInt returns the named param parsed as an int. It returns an error
+// if the param does not exist or is not a valid int.
+func (context *Context) ParamInt(name string) (int, error) {
+	value, found := context.Params[name]
+	if !found {
+		return 0, fmt.Errorf("router: no param named %q", name)
+	}
+	return strconv.Atoi(value)
+}
+
+// ParamBool returns the named param parsed as a bool, using the same
+// format as strconv.ParseBool (e.g. "1", "t", "true"). It returns an error
+// if the param does not exist or is not a valid bool.
+func (context *Context) ParamBool(name string) (bool, error) {
+	value, found := context.Params[name]
+	if !found {
+		return false, fmt.Errorf("router: no param named %q", name)
+	}
+	return strconv.ParseBool(value)
+}
+
+// QueryDefault returns the first value associated with name in the query
+// string, or fallback if name is not present.
+func (context *Context) QueryDefault(name string, fallback string) string {
+	if values, found := context.Query[name]; found && len(values) > 0 {
+		return values[0]
+	}
+	return fallback
+}
+
+// splitQuery splits raw into a path and a query string, as found after a
+// "?" in raw. If raw has no "?", query is "".
+func splitQuery(raw string) (path string, query string) {
+	if idx := strings.IndexByte(raw, '?'); idx != -1 {
+		return raw[:idx], raw[idx+1:]
+	}
+	return raw, ""
+}
+
+// parseQuery parses a raw query string (without the leading "?") into a
+// url.Values. Malformed parameters are silently ignored, matching the
+// behavior of url.ParseQuery.
+func parseQuery(raw string) url.Values {
+	values, _ := url.ParseQuery(raw)
+	return values
+}
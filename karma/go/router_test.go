@@ -11,6 +11,7 @@ import (
 	"github.com/gopherjs/gopherjs/js"
 	"github.com/rusco/qunit"
 	"honnef.co/go/js/dom"
+	"net/url"
 	"strings"
 	"time"
 )
@@ -28,6 +29,7 @@ var (
 type route struct {
 	path   string
 	params map[string]string
+	query  url.Values
 }
 
 func main() {
@@ -84,6 +86,37 @@ func main() {
 		}()
 	})
 
+	qunit.Test("Navigate with Query", func(assert qunit.QUnitAssert) {
+		qunit.Expect(3)
+		done := assert.Async()
+
+		go func() {
+			// Set up router
+			r := router.New()
+			routeChan := make(chan route)
+			r.HandleFunc("/foo/{param1}", newChanHandlerFunc("/foo/{param1}", routeChan))
+			r.Start()
+			defer r.Stop()
+
+			// Navigate to a path with both a named parameter and a query string.
+			// The route should still match on the path alone, with the query
+			// string parsed separately into Context.Query.
+			go r.Navigate("/foo/bar?x=1")
+			select {
+			case gotRoute := <-routeChan:
+				checkPath(assert, "/foo/bar")
+				assert.Equal(gotRoute.params["param1"], "bar", "Triggered route had incorrect params.")
+				assert.Equal(gotRoute.query.Get("x"), "1", "Triggered route had incorrect query.")
+				done()
+			case <-time.After(200 * time.Millisecond):
+				assert.Ok(false, "Route /foo/{param1} was not triggered within 200 milliseconds")
+				assert.Ok(true, "")
+				assert.Ok(true, "")
+				done()
+			}
+		}()
+	})
+
 	qunit.Test("Navigate Back", func(assert qunit.QUnitAssert) {
 		qunit.Expect(3)
 		done := assert.Async()
@@ -157,6 +190,7 @@ func newChanHandlerFunc(path string, routeChan chan route) router.Handler {
 		routeChan <- route{
 			path:   path,
 			params: context.Params,
+			query:  context.Query,
 		}
 	}
 }
@@ -0,0 +1,129 @@
+// Copyright 2015 Alex Browne and Soroush Pour.
+// Allrights reserved. Use of this source code is
+// governed by the MIT license, which can be found
+// in the LICENSE file.
+
+package router
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// urlToken matches one "{name}" or "{name:regex}" placeholder, or a
+// trailing "*name" catch-all, within a registered pattern. It is used by
+// URL to find the pieces of a pattern that need to be substituted with a
+// real value.
+var urlToken = regexp.MustCompile(`\{([^{}:]+)(?::[^{}]+)?\}|\*(.+)$`)
+
+// HandleFuncNamed registers handler to be run whenever the current url
+// matches pattern, exactly like HandleFunc, and also records name so that
+// URL (and NavigateNamed) can later build a concrete url for this route by
+// substituting parameter values into pattern. This decouples link
+// generation from the concrete url scheme: renaming
+// "/foo/{param1}/{param2}" to "/foo/{param1}-{param2}" only requires
+// updating the pattern passed to HandleFuncNamed, not every call site that
+// navigates to it. It returns an error under the same conditions as
+// HandleFunc, or if name was already registered.
+func (router *Router) HandleFuncNamed(name string, pattern string, handler Handler) error {
+	if _, found := router.names[name]; found {
+		return fmt.Errorf("router: a route named %q is already registered", name)
+	}
+	fullPattern := joinPattern(router.prefix, pattern)
+	if err := router.tree.add(fullPattern, applyMiddleware(handler, router.middleware)); err != nil {
+		return err
+	}
+	router.names[name] = fullPattern
+	return nil
+}
+
+// URL builds and returns the url for the route registered under name via
+// HandleFuncNamed, substituting params into the pattern's placeholders and
+// url-escaping each value. params must be an even number of arguments,
+// alternating parameter names and values (e.g. URL("user", "id", "24")).
+// It returns an error if name was never registered, or if a value for one
+// of the pattern's parameters is missing.
+func (router *Router) URL(name string, params ...string) (string, error) {
+	pattern, found := router.names[name]
+	if !found {
+		return "", fmt.Errorf("router: no route named %q was registered", name)
+	}
+	if len(params)%2 != 0 {
+		return "", fmt.Errorf("router: URL requires params as (name, value) pairs, got %d arguments", len(params))
+	}
+	values := make(map[string]string, len(params)/2)
+	for i := 0; i < len(params); i += 2 {
+		values[params[i]] = params[i+1]
+	}
+	return buildURL(pattern, values)
+}
+
+// NavigateNamed builds the url for the route registered under name (see
+// URL) and navigates to it, exactly as if Navigate had been called with
+// that url directly.
+func (router *Router) NavigateNamed(name string, params map[string]string) error {
+	args := make([]string, 0, len(params)*2)
+	for paramName, value := range params {
+		args = append(args, paramName, value)
+	}
+	path, err := router.URL(name, args...)
+	if err != nil {
+		return err
+	}
+	router.Navigate(path)
+	return nil
+}
+
+// buildURL substitutes every "{name}", "{name:regex}", or "*name"
+// placeholder in pattern with the url-escaped value from values, returning
+// an error if pattern references a name that is missing from values. A
+// catch-all's value is escaped one "/"-separated piece at a time, since it
+// represents multiple path segments rather than a single one.
+func buildURL(pattern string, values map[string]string) (string, error) {
+	var built strings.Builder
+	last := 0
+	for _, m := range urlToken.FindAllStringSubmatchIndex(pattern, -1) {
+		isCatchAll := m[4] != -1
+		name := submatch(pattern, m, 2)
+		if isCatchAll {
+			name = submatch(pattern, m, 4)
+		}
+		value, found := values[name]
+		if !found {
+			return "", fmt.Errorf("router: missing value for parameter %q in pattern %s", name, pattern)
+		}
+		built.WriteString(pattern[last:m[0]])
+		if isCatchAll {
+			built.WriteString(escapePathSegments(value))
+		} else {
+			built.WriteString(url.PathEscape(value))
+		}
+		last = m[1]
+	}
+	built.WriteString(pattern[last:])
+	return built.String(), nil
+}
+
+// escapePathSegments url-escapes each "/"-separated piece of value
+// independently and rejoins them with "/", so a multi-segment catch-all
+// value round-trips as multiple path segments instead of having its "/"
+// escaped away as a single opaque segment.
+func escapePathSegments(value string) string {
+	segments := strings.Split(value, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// submatch returns the text captured by submatch group i (as produced by
+// FindAllStringSubmatchIndex), or "" if that group did not participate in
+// the match.
+func submatch(s string, m []int, i int) string {
+	if m[i] == -1 {
+		return ""
+	}
+	return s[m[i]:m[i+1]]
+}
@@ -0,0 +1,91 @@
+// Copyright 2015 Alex Browne and Soroush Pour.
+// Allrights reserved. Use of this source code is
+// governed by the MIT license, which can be found
+// in the LICENSE file.
+
+package router
+
+import "testing"
+
+func TestHandleFuncNamedAndURL(t *testing.T) {
+	r := New()
+	if err := r.HandleFuncNamed("user", "/users/{id}", noopHandler); err != nil {
+		t.Fatalf("HandleFuncNamed returned unexpected error: %s", err)
+	}
+	got, err := r.URL("user", "id", "24")
+	if err != nil {
+		t.Fatalf("URL returned unexpected error: %s", err)
+	}
+	if want := "/users/24"; got != want {
+		t.Errorf("expected URL to return %q, got %q", want, got)
+	}
+}
+
+func TestHandleFuncNamedDuplicateName(t *testing.T) {
+	r := New()
+	if err := r.HandleFuncNamed("user", "/users/{id}", noopHandler); err != nil {
+		t.Fatalf("HandleFuncNamed returned unexpected error: %s", err)
+	}
+	if err := r.HandleFuncNamed("user", "/accounts/{id}", noopHandler); err == nil {
+		t.Error("expected an error registering the name \"user\" twice, got nil")
+	}
+}
+
+func TestURLUnknownName(t *testing.T) {
+	r := New()
+	if _, err := r.URL("missing"); err == nil {
+		t.Error("expected an error building a url for an unregistered name, got nil")
+	}
+}
+
+func TestURLMissingParam(t *testing.T) {
+	r := New()
+	if err := r.HandleFuncNamed("user", "/users/{id}", noopHandler); err != nil {
+		t.Fatalf("HandleFuncNamed returned unexpected error: %s", err)
+	}
+	if _, err := r.URL("user"); err == nil {
+		t.Error("expected an error building a url with a missing param, got nil")
+	}
+}
+
+func TestURLEscapesValues(t *testing.T) {
+	r := New()
+	if err := r.HandleFuncNamed("search", "/search/{term}", noopHandler); err != nil {
+		t.Fatalf("HandleFuncNamed returned unexpected error: %s", err)
+	}
+	got, err := r.URL("search", "term", "a b")
+	if err != nil {
+		t.Fatalf("URL returned unexpected error: %s", err)
+	}
+	if want := "/search/a%20b"; got != want {
+		t.Errorf("expected URL to return %q, got %q", want, got)
+	}
+}
+
+func TestURLCatchAllPreservesSlashes(t *testing.T) {
+	r := New()
+	if err := r.HandleFuncNamed("file", "/files/*rest", noopHandler); err != nil {
+		t.Fatalf("HandleFuncNamed returned unexpected error: %s", err)
+	}
+	got, err := r.URL("file", "rest", "a/b/c")
+	if err != nil {
+		t.Fatalf("URL returned unexpected error: %s", err)
+	}
+	if want := "/files/a/b/c"; got != want {
+		t.Errorf("expected URL to preserve \"/\" in a catch-all value, got %q", got)
+	}
+}
+
+func TestURLCatchAllEscapesEachSegment(t *testing.T) {
+	r := New()
+	if err := r.HandleFuncNamed("file", "/files/*rest", noopHandler); err != nil {
+		t.Fatalf("HandleFuncNamed returned unexpected error: %s", err)
+	}
+	got, err := r.URL("file", "rest", "a b/c")
+	if err != nil {
+		t.Fatalf("URL returned unexpected error: %s", err)
+	}
+	if want := "/files/a%20b/c"; got != want {
+		t.Errorf("expected each catch-all segment to be escaped independently, got %q", got)
+	}
+}
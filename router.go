@@ -0,0 +1,385 @@
+// Copyright 2015 Alex Browne and Soroush Pour.
+// Allrights reserved. Use of this source code is
+// governed by the MIT license, which can be found
+// in the LICENSE file.
+
+// Package router implements a client-side router for single-page
+// applications (SPAs) compiled with GopherJS. Routes are registered with
+// HandleFunc and are matched against a radix tree, so a handler is found in
+// time proportional to the length of the path rather than the number of
+// registered routes. The router triggers the matching Handler whenever the
+// url changes, whether because of a call to Navigate, the user clicking the
+// back/forward button, or a click on a link that has been intercepted with
+// InterceptLinks.
+package router
+
+import (
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/gopherjs/gopherjs/js"
+	"honnef.co/go/js/dom"
+)
+
+// window and document are resolved lazily, the first time they are needed,
+// rather than at package init time. dom.GetWindow panics outside a browser,
+// and this package's other files (tree.go, named.go, context.go) are pure
+// Go with their own tests that run under plain `go test`; resolving eagerly
+// would crash the whole test binary before any test body ran.
+var (
+	windowOnce sync.Once
+	windowVal  dom.Window
+
+	documentOnce sync.Once
+	documentVal  dom.Document
+)
+
+func window() dom.Window {
+	windowOnce.Do(func() {
+		windowVal = dom.GetWindow()
+	})
+	return windowVal
+}
+
+func document() dom.Document {
+	documentOnce.Do(func() {
+		documentVal = window().Document()
+	})
+	return documentVal
+}
+
+var (
+	browserSupportsPushStateOnce sync.Once
+	browserSupportsPushStateVal  bool
+)
+
+// browserSupportsPushState returns true if the current browser supports
+// history.pushState and the onpopstate event. If it does not, the router
+// falls back to using the location hash and the onhashchange event. Like
+// window and document above, this is resolved lazily rather than at package
+// init time, since js.Global is only safe to touch inside a browser.
+func browserSupportsPushState() bool {
+	browserSupportsPushStateOnce.Do(func() {
+		browserSupportsPushStateVal = (js.Global.Get("onpopstate") != js.Undefined) &&
+			(js.Global.Get("history") != js.Undefined) &&
+			(js.Global.Get("history").Get("pushState") != js.Undefined)
+	})
+	return browserSupportsPushStateVal
+}
+
+// Handler is a function that is run whenever its associated route is
+// triggered. context holds any parameters parsed out of the matched path.
+type Handler func(context *Context)
+
+// Middleware wraps a Handler to add cross-cutting behavior (e.g. an
+// auth-guard or analytics) to it. It is registered with Router.Use.
+type Middleware func(Handler) Handler
+
+// Router matches the current url against a set of registered routes and
+// triggers the corresponding Handler whenever the url changes.
+type Router struct {
+	// tree is shared by a Router and every Router returned from its Group
+	// method, since they all register routes into the same radix tree.
+	tree *node
+	// prefix is prepended to every pattern registered on this Router. It
+	// is empty for the root Router returned by New.
+	prefix string
+	// middleware is applied, in registration order, to every Handler
+	// registered on this Router.
+	middleware []Middleware
+	// names maps the name of every route registered with HandleFuncNamed
+	// to its full pattern, for use by URL. It is shared with every Router
+	// returned from Group.
+	names map[string]string
+
+	// NotFoundHandler, if set, is run whenever the current url matches no
+	// registered route. It receives the unmatched path, with any query
+	// string parsed into Context.Query.
+	NotFoundHandler Handler
+	// RedirectTrailingSlash, if true, causes a path that matches a route
+	// but differs from the route's pattern by a trailing slash (e.g.
+	// "/foo/" when "/foo" is registered) to be redirected to the pattern's
+	// form via history.replaceState, so the redirect doesn't leave an
+	// extra entry in the browser history for Back to stumble over.
+	// Matching itself ignores a trailing slash, so the route's Handler
+	// runs whether or not this is set.
+	RedirectTrailingSlash bool
+	// CleanPath, if true, causes every path to be cleaned before
+	// matching: collapsing repeated "/" and resolving "." and ".."
+	// segments, the same algorithm httprouter uses. If cleaning changes
+	// the path, the router redirects to the cleaned path via
+	// history.replaceState before matching it, so that pasted or
+	// hand-typed urls behave sanely.
+	CleanPath bool
+
+	started bool
+
+	// *EventTarget.AddEventListener returns a wrapper function that must
+	// be passed back to RemoveEventListener, so that's what these store
+	// rather than the original listener func passed in to AddEventListener.
+	popStateListener   func(*js.Object)
+	hashChangeListener func(*js.Object)
+	clickListener      func(*js.Object)
+}
+
+// New creates and returns a new Router. The router does not start
+// listening for changes to the url until Start is called.
+func New() *Router {
+	return &Router{
+		tree:  newNode(),
+		names: map[string]string{},
+	}
+}
+
+// HandleFunc registers handler to be run whenever the current url matches
+// pattern. A pattern is made up of one or more "/"-separated segments.
+// Each segment is either static text (e.g. "foo"), a named parameter
+// written as "{name}" (optionally constrained by an inline regex, e.g.
+// "{id:[0-9]+}"), or, as the last segment only, a catch-all written as
+// "*name" which matches the remainder of the path. A segment may also mix
+// literal text with one or more parameters, e.g.
+// "{name:[a-z0-9\-]+}.{ext:png|jpg}". HandleFunc returns an error if
+// pattern is malformed (e.g. an invalid regex constraint) or ambiguous
+// with a route that was already registered.
+func (router *Router) HandleFunc(pattern string, handler Handler) error {
+	fullPattern := joinPattern(router.prefix, pattern)
+	return router.tree.add(fullPattern, applyMiddleware(handler, router.middleware))
+}
+
+// Use appends middleware to the Router's middleware chain. Middleware is
+// applied when a handler is registered, so it only wraps handlers
+// registered after the call to Use. Middleware registered on a group only
+// wraps handlers registered on that group (and any of its subgroups
+// created afterward); middleware registered on the root Router wraps every
+// handler registered afterward on it or on groups created afterward.
+func (router *Router) Use(middleware Middleware) {
+	router.middleware = append(router.middleware, middleware)
+}
+
+// Group returns a new Router representing the routes rooted at prefix.
+// Patterns registered on the returned Router are automatically prefixed,
+// so that e.g. admin := r.Group("/admin"); admin.HandleFunc("/users/{id}",
+// h) registers "/admin/users/{id}", and groups compose: a subgroup of a
+// subgroup concatenates both prefixes. The returned Router inherits a copy
+// of this Router's middleware, so any Middleware registered on it afterward
+// applies only to routes registered on the group. Groups share the same
+// underlying route tree as the Router they were created from, so Start,
+// Stop, and Navigate should always be called on the top-level Router.
+func (router *Router) Group(prefix string) *Router {
+	middleware := make([]Middleware, len(router.middleware))
+	copy(middleware, router.middleware)
+	return &Router{
+		tree:       router.tree,
+		prefix:     joinPattern(router.prefix, prefix),
+		middleware: middleware,
+		names:      router.names,
+	}
+}
+
+// joinPattern joins prefix and pattern into a single "/"-rooted pattern,
+// collapsing any duplicate slashes where they meet.
+func joinPattern(prefix string, pattern string) string {
+	joined := strings.Trim(prefix, "/") + "/" + strings.Trim(pattern, "/")
+	return "/" + strings.Trim(joined, "/")
+}
+
+// applyMiddleware wraps handler with middleware in registration order, so
+// that the first registered Middleware is the outermost (i.e. it runs
+// first and wraps every other Middleware and the handler itself).
+func applyMiddleware(handler Handler, middleware []Middleware) Handler {
+	for i := len(middleware) - 1; i >= 0; i-- {
+		handler = middleware[i](handler)
+	}
+	return handler
+}
+
+// Start starts listening for changes to the url, either via
+// history.pushState (if the browser supports it) or via the location hash.
+// It also triggers the route for the current url, if any route matches it.
+func (router *Router) Start() *Router {
+	if router.started {
+		return router
+	}
+	router.started = true
+	if browserSupportsPushState() {
+		router.popStateListener = window().AddEventListener("popstate", false, func(dom.Event) {
+			router.runHandler(router.currentPath())
+		})
+	} else {
+		router.hashChangeListener = window().AddEventListener("hashchange", false, func(dom.Event) {
+			router.runHandler(currentHashPath())
+		})
+	}
+	router.runHandler(router.currentPath())
+	return router
+}
+
+// Stop stops the router from listening for any further changes to the url.
+func (router *Router) Stop() {
+	if !router.started {
+		return
+	}
+	router.started = false
+	if router.popStateListener != nil {
+		window().RemoveEventListener("popstate", false, router.popStateListener)
+		router.popStateListener = nil
+	}
+	if router.hashChangeListener != nil {
+		window().RemoveEventListener("hashchange", false, router.hashChangeListener)
+		router.hashChangeListener = nil
+	}
+	if router.clickListener != nil {
+		document().RemoveEventListener("click", false, router.clickListener)
+		router.clickListener = nil
+	}
+}
+
+// Navigate changes the current url to path and triggers the appropriate
+// Handler, if any route matches path.
+func (router *Router) Navigate(path string) {
+	if browserSupportsPushState() {
+		window().History().PushState(nil, "", path)
+	} else {
+		window().Location().Hash = path
+	}
+	router.runHandler(path)
+}
+
+// replace changes the current url to path without creating a new browser
+// history entry, unlike Navigate. It is used for redirects (CleanPath,
+// RedirectTrailingSlash) that should be invisible to the back button.
+func (router *Router) replace(path string) {
+	if browserSupportsPushState() {
+		window().History().ReplaceState(nil, "", path)
+	} else {
+		js.Global.Get("location").Call("replace", "#"+path)
+	}
+}
+
+// Back is equivalent to the user clicking the back button in the browser.
+// It triggers the router's popstate or hashchange listener, which in turn
+// triggers the corresponding Handler for the previous url.
+func (router *Router) Back() {
+	window().History().Back()
+}
+
+// InterceptLinks intercepts click events on <a> elements anywhere in the
+// document and, instead of letting the browser navigate normally, calls
+// Navigate with the link's href. This allows links to work the same way
+// whether or not javascript is enabled, while still being handled
+// client-side when it is.
+func (router *Router) InterceptLinks() {
+	router.clickListener = document().AddEventListener("click", false, func(event dom.Event) {
+		var anchor *dom.HTMLAnchorElement
+		var target dom.Node = event.Target()
+		for target != nil {
+			if a, ok := target.(*dom.HTMLAnchorElement); ok {
+				anchor = a
+				break
+			}
+			target = target.ParentNode()
+		}
+		if anchor == nil {
+			return
+		}
+		href := anchor.GetAttribute("href")
+		if href == "" {
+			return
+		}
+		event.PreventDefault()
+		router.Navigate(href)
+	})
+}
+
+// currentPath returns the path the browser currently considers itself to be
+// at, including any query string, using the pathname and search or the
+// location hash depending on browser support.
+func (router *Router) currentPath() string {
+	if browserSupportsPushState() {
+		location := window().Location()
+		return location.Pathname + location.Search
+	}
+	return currentHashPath()
+}
+
+// currentHashPath returns the path portion of the current location hash
+// (i.e. everything after the leading "#").
+func currentHashPath() string {
+	hash := window().Location().Hash
+	if len(hash) == 0 {
+		return "/"
+	}
+	return hash[1:]
+}
+
+// runHandler splits any query string off of raw and finds the route
+// matching the remaining path. If CleanPath is set, the path is cleaned
+// (redirecting the url if that changes it) before matching. If
+// RedirectTrailingSlash is set and the path differs from the matched
+// route's pattern by a trailing slash, the url is redirected to the
+// pattern's form. It then runs the matched route's Handler, or, if no
+// route matches, NotFoundHandler, if set.
+func (router *Router) runHandler(raw string) {
+	path, query := splitQuery(raw)
+	if router.CleanPath {
+		if cleaned := cleanPath(path); cleaned != path {
+			router.replace(joinQuery(cleaned, query))
+			path = cleaned
+		}
+	}
+	match, params := router.tree.match(path)
+	if match != nil && router.RedirectTrailingSlash {
+		// Matching ignores a trailing slash, so path can differ from the
+		// matched pattern by one; redirect to the pattern's form if so.
+		if strings.HasSuffix(path, "/") != strings.HasSuffix(match.pattern, "/") {
+			path = toggleTrailingSlash(path)
+			router.replace(joinQuery(path, query))
+		}
+	}
+	queryValues := parseQuery(query)
+	if match == nil {
+		if router.NotFoundHandler != nil {
+			router.NotFoundHandler(newContext(path, "", map[string]string{}, queryValues))
+		}
+		return
+	}
+	if params == nil {
+		params = map[string]string{}
+	}
+	match.handler(newContext(path, match.pattern, params, queryValues))
+}
+
+// joinQuery rejoins path and query (as split by splitQuery) into a single
+// url, omitting the "?" entirely when query is empty.
+func joinQuery(path string, query string) string {
+	if query == "" {
+		return path
+	}
+	return path + "?" + query
+}
+
+// cleanPath collapses repeated "/" and resolves "." and ".." segments in p,
+// the same algorithm httprouter uses, while preserving a single trailing
+// slash if p had one.
+func cleanPath(p string) string {
+	if p == "" {
+		return "/"
+	}
+	cleaned := path.Clean(p)
+	if !strings.HasPrefix(cleaned, "/") {
+		cleaned = "/" + cleaned
+	}
+	if len(p) > 1 && strings.HasSuffix(p, "/") && !strings.HasSuffix(cleaned, "/") {
+		cleaned += "/"
+	}
+	return cleaned
+}
+
+// toggleTrailingSlash returns p with its trailing slash added or removed.
+func toggleTrailingSlash(p string) string {
+	if len(p) > 1 && strings.HasSuffix(p, "/") {
+		return strings.TrimSuffix(p, "/")
+	}
+	return p + "/"
+}
@@ -0,0 +1,125 @@
+// Copyright 2015 Alex Browne and Soroush Pour.
+// Allrights reserved. Use of this source code is
+// governed by the MIT license, which can be found
+// in the LICENSE file.
+
+package router
+
+import "testing"
+
+func TestGroupComposesPrefixes(t *testing.T) {
+	r := New()
+	admin := r.Group("/admin")
+	reports := admin.Group("/reports")
+	if err := reports.HandleFunc("/{id}", noopHandler); err != nil {
+		t.Fatalf("HandleFunc returned unexpected error: %s", err)
+	}
+	match, params := r.tree.match("/admin/reports/42")
+	if match == nil {
+		t.Fatal("expected /admin/reports/42 to match, but it did not")
+	}
+	if params["id"] != "42" {
+		t.Errorf("expected params[\"id\"] to be \"42\", got %q", params["id"])
+	}
+}
+
+func TestUseAppliesMiddlewareOutermostFirst(t *testing.T) {
+	r := New()
+	var order []string
+	trace := func(name string) Middleware {
+		return func(next Handler) Handler {
+			return func(c *Context) {
+				order = append(order, name+":before")
+				next(c)
+				order = append(order, name+":after")
+			}
+		}
+	}
+	r.Use(trace("first"))
+	r.Use(trace("second"))
+	if err := r.HandleFunc("/foo", func(*Context) {
+		order = append(order, "handler")
+	}); err != nil {
+		t.Fatalf("HandleFunc returned unexpected error: %s", err)
+	}
+	match, params := r.tree.match("/foo")
+	if match == nil {
+		t.Fatal("expected /foo to match, but it did not")
+	}
+	match.handler(newContext("/foo", match.pattern, params, nil))
+	expected := []string{"first:before", "second:before", "handler", "second:after", "first:after"}
+	if len(order) != len(expected) {
+		t.Fatalf("expected call order %v, got %v", expected, order)
+	}
+	for i, name := range expected {
+		if order[i] != name {
+			t.Errorf("expected call order %v, got %v", expected, order)
+			break
+		}
+	}
+}
+
+func TestCleanPathCollapsesSlashesAndResolvesDots(t *testing.T) {
+	cases := map[string]string{
+		"/foo//bar":    "/foo/bar",
+		"/foo/./bar":   "/foo/bar",
+		"/foo/../bar":  "/bar",
+		"/foo/bar/..":  "/foo",
+		"/foo/bar/":    "/foo/bar/",
+		"/foo//bar///": "/foo/bar/",
+		"":             "/",
+	}
+	for in, want := range cases {
+		if got := cleanPath(in); got != want {
+			t.Errorf("cleanPath(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestToggleTrailingSlash(t *testing.T) {
+	if got := toggleTrailingSlash("/foo"); got != "/foo/" {
+		t.Errorf(`toggleTrailingSlash("/foo") = %q, want "/foo/"`, got)
+	}
+	if got := toggleTrailingSlash("/foo/"); got != "/foo" {
+		t.Errorf(`toggleTrailingSlash("/foo/") = %q, want "/foo"`, got)
+	}
+}
+
+func TestNotFoundHandlerReceivesUnmatchedPathAndQuery(t *testing.T) {
+	r := New()
+	var gotPath, gotQuery string
+	r.NotFoundHandler = func(c *Context) {
+		gotPath = c.Path
+		gotQuery = c.QueryDefault("x", "")
+	}
+	r.runHandler("/nope?x=1")
+	if gotPath != "/nope" {
+		t.Errorf(`expected NotFoundHandler to receive Path "/nope", got %q`, gotPath)
+	}
+	if gotQuery != "1" {
+		t.Errorf(`expected NotFoundHandler to receive Query["x"] "1", got %q`, gotQuery)
+	}
+}
+
+func TestGroupMiddlewareOnlyAppliesWithinGroup(t *testing.T) {
+	r := New()
+	var touched bool
+	admin := r.Group("/admin")
+	admin.Use(func(next Handler) Handler {
+		return func(c *Context) {
+			touched = true
+			next(c)
+		}
+	})
+	if err := r.HandleFunc("/public", noopHandler); err != nil {
+		t.Fatalf("HandleFunc returned unexpected error: %s", err)
+	}
+	match, params := r.tree.match("/public")
+	if match == nil {
+		t.Fatal("expected /public to match, but it did not")
+	}
+	match.handler(newContext("/public", match.pattern, params, nil))
+	if touched {
+		t.Error("expected middleware registered on a group not to apply to a route registered on the parent Router")
+	}
+}
@@ -0,0 +1,310 @@
+// Copyright 2015 Alex Browne and Soroush Pour.
+// Allrights reserved. Use of this source code is
+// governed by the MIT license, which can be found
+// in the LICENSE file.
+
+package router
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// nodeType describes what kind of path segment a node represents.
+type nodeType uint8
+
+const (
+	staticNode nodeType = iota
+	paramNode
+	catchAllNode
+	patternNode
+)
+
+// singleParam matches a segment that is made up of exactly one parameter
+// and nothing else, optionally with an inline regex constraint, e.g.
+// "{id}" or "{id:[0-9]+}".
+var singleParam = regexp.MustCompile(`^\{([^{}:]+)(?::([^{}]+))?\}$`)
+
+// paramToken matches one "{name}" or "{name:regex}" placeholder within a
+// segment that mixes literal text and parameters, e.g. the "{ext:png|jpg}"
+// in "*.{ext:png|jpg}".
+var paramToken = regexp.MustCompile(`\{([^{}:]+)(?::([^{}]+))?\}`)
+
+// node is a single node in the router's radix tree. Each node corresponds
+// to one "/"-separated segment of a registered pattern. Static children are
+// bucketed by the first byte of their segment, so matching a path only ever
+// does O(number of segments) work instead of scanning every registered
+// route.
+type node struct {
+	nodeType nodeType
+
+	// segment is the static text for a staticNode, the parameter or
+	// catch-all name (without the surrounding "{}" or leading "*") for a
+	// paramNode or catchAllNode, or the raw, not-yet-compiled pattern
+	// text for a patternNode.
+	segment string
+
+	// regex constrains the value a paramNode may match (set only when the
+	// pattern included a ":regex" constraint), or is the compiled regex
+	// for an entire patternNode segment. It is compiled once, at
+	// registration, and reused on every match.
+	regex *regexp.Regexp
+	// paramNames holds the names of the capture groups in regex, in
+	// order, for a patternNode.
+	paramNames []string
+
+	// pattern and handler are only set on the node where a route was
+	// actually registered, i.e. the node representing the last segment
+	// of some pattern.
+	pattern string
+	handler Handler
+
+	staticChildren  map[byte][]*node
+	paramChild      *node
+	patternChildren []*node
+	catchAllChild   *node
+}
+
+// newNode creates and returns an empty node.
+func newNode() *node {
+	return &node{staticChildren: map[byte][]*node{}}
+}
+
+// splitSegments splits a pattern like "/foo/{bar}/*rest" into its
+// "/"-separated segments, ignoring any leading or trailing slash.
+func splitSegments(path string) []string {
+	trimmed := strings.Trim(path, "/")
+	if trimmed == "" {
+		return nil
+	}
+	return strings.Split(trimmed, "/")
+}
+
+// add inserts handler into the tree under pattern. It returns an error if
+// pattern is ambiguous with a route that is already registered (e.g. two
+// different parameter names at the same position, or a static segment
+// conflicting with a parameter segment), or if pattern uses a catch-all
+// segment anywhere but last.
+func (root *node) add(pattern string, handler Handler) error {
+	current := root
+	segments := splitSegments(pattern)
+	for i, segment := range segments {
+		child, err := current.child(segment)
+		if err != nil {
+			return fmt.Errorf("router: could not register pattern %s: %s", pattern, err)
+		}
+		if child.nodeType == catchAllNode && i != len(segments)-1 {
+			return fmt.Errorf("router: could not register pattern %s: *%s may only appear as the last path segment", pattern, child.segment)
+		}
+		current = child
+	}
+	if current.handler != nil {
+		return fmt.Errorf("router: pattern %s is ambiguous with already registered pattern %s", pattern, current.pattern)
+	}
+	current.pattern = pattern
+	current.handler = handler
+	return nil
+}
+
+// child returns the child of current which corresponds to segment,
+// creating it first if needed. It returns an error if segment conflicts
+// with a child of a different type that is already registered at the same
+// position.
+func (current *node) child(segment string) (*node, error) {
+	switch {
+	case singleParam.MatchString(segment):
+		m := singleParam.FindStringSubmatch(segment)
+		name, regexSrc := m[1], m[2]
+		if current.paramChild != nil {
+			if current.paramChild.segment != name {
+				return nil, fmt.Errorf("parameter {%s} conflicts with already registered parameter {%s} at the same position", name, current.paramChild.segment)
+			}
+			if existing := current.paramChild.regexSource(); existing != regexSrc {
+				return nil, fmt.Errorf("parameter {%s} has constraint %q, which conflicts with already registered constraint %q", name, regexSrc, existing)
+			}
+			return current.paramChild, nil
+		}
+		// An unconstrained param matches anything, so it is rejected as
+		// ambiguous with any static or pattern sibling at the same
+		// position. A constrained param only matches values satisfying
+		// its regex, so it may coexist with them; the conflict is left
+		// for match time, which tries static children first, then the
+		// param if its regex matches, then pattern children.
+		if regexSrc == "" {
+			if len(current.staticChildren) > 0 {
+				return nil, fmt.Errorf("parameter {%s} conflicts with a static route at the same position", name)
+			}
+			if len(current.patternChildren) > 0 {
+				return nil, fmt.Errorf("parameter {%s} conflicts with a pattern route at the same position", name)
+			}
+		}
+		current.paramChild = newNode()
+		current.paramChild.nodeType = paramNode
+		current.paramChild.segment = name
+		if regexSrc != "" {
+			re, err := regexp.Compile("^(?:" + regexSrc + ")$")
+			if err != nil {
+				return nil, fmt.Errorf("invalid regex constraint %q for parameter {%s}: %s", regexSrc, name, err)
+			}
+			current.paramChild.regex = re
+		}
+		return current.paramChild, nil
+	case strings.Contains(segment, "{"):
+		if current.paramChild != nil && current.paramChild.regex == nil {
+			return nil, fmt.Errorf("pattern %q conflicts with already registered parameter {%s} at the same position", segment, current.paramChild.segment)
+		}
+		for _, child := range current.patternChildren {
+			if child.segment == segment {
+				return child, nil
+			}
+		}
+		re, names, err := compileSegmentPattern(segment)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern segment %q: %s", segment, err)
+		}
+		child := newNode()
+		child.nodeType = patternNode
+		child.segment = segment
+		child.regex = re
+		child.paramNames = names
+		current.patternChildren = append(current.patternChildren, child)
+		return child, nil
+	case strings.HasPrefix(segment, "*"):
+		name := segment[1:]
+		if current.catchAllChild != nil && current.catchAllChild.segment != name {
+			return nil, fmt.Errorf("catch-all *%s conflicts with already registered catch-all *%s at the same position", name, current.catchAllChild.segment)
+		}
+		if current.catchAllChild == nil {
+			current.catchAllChild = newNode()
+			current.catchAllChild.nodeType = catchAllNode
+			current.catchAllChild.segment = name
+		}
+		return current.catchAllChild, nil
+	default:
+		if current.paramChild != nil && current.paramChild.regex == nil {
+			return nil, fmt.Errorf("static route %s conflicts with already registered parameter {%s} at the same position", segment, current.paramChild.segment)
+		}
+		key := segment[0]
+		for _, child := range current.staticChildren[key] {
+			if child.segment == segment {
+				return child, nil
+			}
+		}
+		child := newNode()
+		child.nodeType = staticNode
+		child.segment = segment
+		current.staticChildren[key] = append(current.staticChildren[key], child)
+		return child, nil
+	}
+}
+
+// regexSource returns the source of a paramNode's regex constraint, or ""
+// if it is unconstrained.
+func (current *node) regexSource() string {
+	if current.regex == nil {
+		return ""
+	}
+	return strings.TrimSuffix(strings.TrimPrefix(current.regex.String(), "^(?:"), ")$")
+}
+
+// compileSegmentPattern compiles a segment that mixes literal text with one
+// or more "{name}" or "{name:regex}" placeholders (e.g.
+// "{name:[a-z0-9\\-]+}.{ext:png|jpg}") into a single regex that is matched
+// against the whole segment, along with the names of its capture groups in
+// order. A placeholder with no inline regex defaults to matching anything
+// but a "/".
+func compileSegmentPattern(segment string) (*regexp.Regexp, []string, error) {
+	var pattern strings.Builder
+	var names []string
+	pattern.WriteString("^")
+	last := 0
+	for _, m := range paramToken.FindAllStringSubmatchIndex(segment, -1) {
+		start, end := m[0], m[1]
+		pattern.WriteString(regexp.QuoteMeta(segment[last:start]))
+		name := segment[m[2]:m[3]]
+		regexSrc := "[^/]+"
+		if m[4] != -1 {
+			regexSrc = segment[m[4]:m[5]]
+		}
+		pattern.WriteString("(" + regexSrc + ")")
+		names = append(names, name)
+		last = end
+	}
+	pattern.WriteString(regexp.QuoteMeta(segment[last:]))
+	pattern.WriteString("$")
+	re, err := regexp.Compile(pattern.String())
+	if err != nil {
+		return nil, nil, err
+	}
+	return re, names, nil
+}
+
+// match walks the tree attempting to match path against a registered
+// pattern. It returns the matching node and any parameters captured along
+// the way, or nil if no pattern matches. params is only allocated once a
+// parameter or catch-all segment is actually traversed, so matching a path
+// made up entirely of static segments allocates no params map (splitting
+// the path into segments still allocates).
+func (root *node) match(path string) (*node, map[string]string) {
+	return root.matchSegments(splitSegments(path), nil)
+}
+
+func (current *node) matchSegments(segments []string, params map[string]string) (*node, map[string]string) {
+	if len(segments) == 0 {
+		if current.handler == nil {
+			return nil, nil
+		}
+		return current, params
+	}
+	segment, rest := segments[0], segments[1:]
+	if segment == "" {
+		// An internal "//" (e.g. "/foo//bar") produces an empty segment
+		// here, since splitSegments only trims the path's leading and
+		// trailing slash. Treat it as a non-match rather than indexing
+		// into it below.
+		return nil, nil
+	}
+	for _, child := range current.staticChildren[segment[0]] {
+		if child.segment == segment {
+			if match, matchParams := child.matchSegments(rest, params); match != nil {
+				return match, matchParams
+			}
+		}
+	}
+	if current.paramChild != nil && (current.paramChild.regex == nil || current.paramChild.regex.MatchString(segment)) {
+		childParams := addParam(params, current.paramChild.segment, segment)
+		if match, matchParams := current.paramChild.matchSegments(rest, childParams); match != nil {
+			return match, matchParams
+		}
+	}
+	for _, child := range current.patternChildren {
+		if m := child.regex.FindStringSubmatch(segment); m != nil {
+			childParams := params
+			for i, name := range child.paramNames {
+				childParams = addParam(childParams, name, m[i+1])
+			}
+			if match, matchParams := child.matchSegments(rest, childParams); match != nil {
+				return match, matchParams
+			}
+		}
+	}
+	if current.catchAllChild != nil && current.catchAllChild.handler != nil {
+		childParams := addParam(params, current.catchAllChild.segment, strings.Join(segments, "/"))
+		return current.catchAllChild, childParams
+	}
+	return nil, nil
+}
+
+// addParam returns a copy of params with name set to value. It allocates a
+// new map on every call and never modifies params, so the parameters
+// matched so far are left untouched for every other branch tried while
+// backtracking.
+func addParam(params map[string]string, name string, value string) map[string]string {
+	clone := make(map[string]string, len(params)+1)
+	for k, v := range params {
+		clone[k] = v
+	}
+	clone[name] = value
+	return clone
+}
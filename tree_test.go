@@ -0,0 +1,183 @@
+// Copyright 2015 Alex Browne and Soroush Pour.
+// Allrights reserved. Use of this source code is
+// governed by the MIT license, which can be found
+// in the LICENSE file.
+
+package router
+
+import "testing"
+
+func noopHandler(*Context) {}
+
+func TestMatchStatic(t *testing.T) {
+	tree := newNode()
+	if err := tree.add("/foo/bar", noopHandler); err != nil {
+		t.Fatalf("add returned unexpected error: %s", err)
+	}
+	match, params := tree.match("/foo/bar")
+	if match == nil {
+		t.Fatal("expected /foo/bar to match, but it did not")
+	}
+	if len(params) != 0 {
+		t.Errorf("expected no params, got %v", params)
+	}
+	if match, _ := tree.match("/foo/baz"); match != nil {
+		t.Error("expected /foo/baz not to match, but it did")
+	}
+}
+
+func TestMatchParam(t *testing.T) {
+	tree := newNode()
+	if err := tree.add("/foo/{param1}/{param2}", noopHandler); err != nil {
+		t.Fatalf("add returned unexpected error: %s", err)
+	}
+	match, params := tree.match("/foo/bar/baz")
+	if match == nil {
+		t.Fatal("expected /foo/bar/baz to match, but it did not")
+	}
+	expected := map[string]string{"param1": "bar", "param2": "baz"}
+	for name, value := range expected {
+		if params[name] != value {
+			t.Errorf("expected params[%q] to be %q, got %q", name, value, params[name])
+		}
+	}
+}
+
+func TestMatchCatchAll(t *testing.T) {
+	tree := newNode()
+	if err := tree.add("/files/*rest", noopHandler); err != nil {
+		t.Fatalf("add returned unexpected error: %s", err)
+	}
+	match, params := tree.match("/files/a/b/c")
+	if match == nil {
+		t.Fatal("expected /files/a/b/c to match, but it did not")
+	}
+	if params["rest"] != "a/b/c" {
+		t.Errorf("expected params[\"rest\"] to be \"a/b/c\", got %q", params["rest"])
+	}
+}
+
+func TestAddCatchAllMustBeLastSegment(t *testing.T) {
+	tree := newNode()
+	if err := tree.add("/files/*rest/extra", noopHandler); err == nil {
+		t.Error("expected an error registering /files/*rest/extra, since *rest is not the last segment, got nil")
+	}
+}
+
+func TestMatchEmptySegmentDoesNotPanic(t *testing.T) {
+	tree := newNode()
+	if err := tree.add("/foo/bar", noopHandler); err != nil {
+		t.Fatalf("add returned unexpected error: %s", err)
+	}
+	// A path with an internal "//" produces an empty segment once split.
+	// It should be treated as a non-match instead of panicking when
+	// indexing into it.
+	if match, _ := tree.match("/foo//bar"); match != nil {
+		t.Error("expected /foo//bar not to match, but it did")
+	}
+}
+
+func TestAddAmbiguousParamNames(t *testing.T) {
+	tree := newNode()
+	if err := tree.add("/foo/{a}", noopHandler); err != nil {
+		t.Fatalf("add returned unexpected error: %s", err)
+	}
+	if err := tree.add("/foo/{b}", noopHandler); err == nil {
+		t.Error("expected an error registering /foo/{b} after /foo/{a}, got nil")
+	}
+}
+
+func TestAddStaticConflictsWithParam(t *testing.T) {
+	tree := newNode()
+	if err := tree.add("/foo/{a}", noopHandler); err != nil {
+		t.Fatalf("add returned unexpected error: %s", err)
+	}
+	if err := tree.add("/foo/bar", noopHandler); err == nil {
+		t.Error("expected an error registering /foo/bar after /foo/{a}, got nil")
+	}
+}
+
+func TestAddDuplicatePatternIsAmbiguous(t *testing.T) {
+	tree := newNode()
+	if err := tree.add("/foo", noopHandler); err != nil {
+		t.Fatalf("add returned unexpected error: %s", err)
+	}
+	if err := tree.add("/foo", noopHandler); err == nil {
+		t.Error("expected an error registering /foo twice, got nil")
+	}
+}
+
+func TestMatchConstrainedParam(t *testing.T) {
+	tree := newNode()
+	if err := tree.add("/users/{id:[0-9]+}", noopHandler); err != nil {
+		t.Fatalf("add returned unexpected error: %s", err)
+	}
+	if err := tree.add("/users/settings", noopHandler); err != nil {
+		t.Fatalf("expected a constrained param to coexist with a static sibling, got error: %s", err)
+	}
+	match, params := tree.match("/users/42")
+	if match == nil {
+		t.Fatal("expected /users/42 to match the constrained param, but it did not")
+	}
+	if params["id"] != "42" {
+		t.Errorf("expected params[\"id\"] to be \"42\", got %q", params["id"])
+	}
+	match, _ = tree.match("/users/settings")
+	if match == nil || match.pattern != "/users/settings" {
+		t.Error("expected /users/settings to match the static route, but it did not")
+	}
+	if match, _ := tree.match("/users/abc"); match != nil {
+		t.Error("expected /users/abc not to match the constrained param, but it did")
+	}
+}
+
+func TestAddConstrainedParamOrderDoesNotMatter(t *testing.T) {
+	tree := newNode()
+	if err := tree.add("/users/settings", noopHandler); err != nil {
+		t.Fatalf("add returned unexpected error: %s", err)
+	}
+	if err := tree.add("/users/{id:[0-9]+}", noopHandler); err != nil {
+		t.Fatalf("expected a constrained param to coexist with a static sibling registered first, got error: %s", err)
+	}
+}
+
+func TestAddUnconstrainedParamConflictsWithPattern(t *testing.T) {
+	tree := newNode()
+	if err := tree.add("/foo/{id}", noopHandler); err != nil {
+		t.Fatalf("add returned unexpected error: %s", err)
+	}
+	if err := tree.add("/foo/{name}.{ext:png|jpg}", noopHandler); err == nil {
+		t.Error("expected an error registering /foo/{name}.{ext:png|jpg} after /foo/{id}, got nil")
+	}
+}
+
+func TestMatchConstrainedParamCoexistsWithPattern(t *testing.T) {
+	tree := newNode()
+	if err := tree.add("/foo/{id:[0-9]+}", noopHandler); err != nil {
+		t.Fatalf("add returned unexpected error: %s", err)
+	}
+	if err := tree.add("/foo/{name}.{ext:png|jpg}", noopHandler); err != nil {
+		t.Fatalf("expected a constrained param to coexist with a pattern sibling, got error: %s", err)
+	}
+	match, params := tree.match("/foo/report.png")
+	if match == nil {
+		t.Fatal("expected /foo/report.png to match the pattern route, but it did not")
+	}
+	if params["name"] != "report" || params["ext"] != "png" {
+		t.Errorf("expected params {name: report, ext: png}, got %v", params)
+	}
+}
+
+func TestMatchPatternWithConstraintOnFirstPlaceholder(t *testing.T) {
+	tree := newNode()
+	if err := tree.add("/files/{name:[a-z0-9\\-]+}.{ext:png|jpg}", noopHandler); err != nil {
+		t.Fatalf("add returned unexpected error: %s", err)
+	}
+	match, params := tree.match("/files/report.png")
+	if match == nil {
+		t.Fatal("expected /files/report.png to match, but it did not")
+	}
+	if params["name"] != "report" || params["ext"] != "png" {
+		t.Errorf("expected params {name: report, ext: png}, got %v", params)
+	}
+}